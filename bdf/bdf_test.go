@@ -0,0 +1,106 @@
+package bdf
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// sampleBDF defines one glyph, 'A' (0x41), as a 2x2 block with the top-right
+// and bottom-left pixels set.
+const sampleBDF = `STARTFONT 2.1
+FONT -test-test-medium-r-normal--8-80-75-75-p-50-iso8859-1
+SIZE 8 75 75
+FONTBOUNDINGBOX 2 2 0 0
+STARTPROPERTIES 1
+FONT_ASCENT 2
+ENDPROPERTIES
+CHARS 1
+STARTCHAR A
+ENCODING 65
+SWIDTH 500 0
+DWIDTH 2 0
+BBX 2 2 0 0
+BITMAP
+40
+80
+ENDCHAR
+ENDFONT
+`
+
+func TestParse(t *testing.T) {
+	font, err := Parse(strings.NewReader(sampleBDF))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if font.BoundingBoxW != 2 || font.BoundingBoxH != 2 {
+		t.Fatalf("bounding box = %dx%d, want 2x2", font.BoundingBoxW, font.BoundingBoxH)
+	}
+	g, ok := font.Glyphs['A']
+	if !ok {
+		t.Fatalf("glyph 'A' not found")
+	}
+	if g.Width != 2 || g.Height != 2 || g.Advance != 2 {
+		t.Fatalf("glyph A = %+v, want Width=2 Height=2 Advance=2", g)
+	}
+	// Top-right set, top-left clear.
+	if g.bit(0, 0) || !g.bit(1, 0) {
+		t.Fatalf("row 0 bits = %v,%v, want false,true", g.bit(0, 0), g.bit(1, 0))
+	}
+	// Bottom-left set, bottom-right clear.
+	if !g.bit(0, 1) || g.bit(1, 1) {
+		t.Fatalf("row 1 bits = %v,%v, want true,false", g.bit(0, 1), g.bit(1, 1))
+	}
+}
+
+func TestParseMissingStartFont(t *testing.T) {
+	if _, err := Parse(strings.NewReader("ENDFONT\n")); err == nil {
+		t.Fatal("expected error for input missing STARTFONT")
+	}
+}
+
+func TestBoundStringScale(t *testing.T) {
+	font, err := Parse(strings.NewReader(sampleBDF))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	cases := []struct {
+		scale int
+		wantW int
+		wantH int
+	}{
+		{scale: 1, wantW: 2, wantH: 2},
+		{scale: 3, wantW: 6, wantH: 6},
+		{scale: 0, wantW: 2, wantH: 2}, // non-positive scale clamps to 1
+	}
+	for _, c := range cases {
+		got := font.BoundString("A", c.scale)
+		if got.Dx() != c.wantW || got.Dy() != c.wantH {
+			t.Errorf("BoundString(%q, %d) = %v, want %dx%d", "A", c.scale, got, c.wantW, c.wantH)
+		}
+	}
+}
+
+func TestDrawStringConcurrentSharedFont(t *testing.T) {
+	font, err := Parse(strings.NewReader(sampleBDF))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	// Two goroutines draw with the same *Font at different scales/colors
+	// concurrently; this must not race since scale/color are now per-call
+	// arguments rather than mutable fields on the shared Font.
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(scale int, col color.Color) {
+			defer wg.Done()
+			dst := image.NewRGBA(image.Rect(0, 0, 20, 20))
+			font.DrawString(dst, image.Pt(0, 0), "A", scale, col)
+		}(i+1, color.Black)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// renderCache is a small in-memory LRU of rendered label bytes, keyed by a
+// hash of the request parameters and output format. It lets repeated
+// requests for the same label (common when a label template is reprinted)
+// skip re-rasterizing, without ever touching disk - the source of the race
+// this replaces, where every request wrote the same static file path.
+type renderCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type renderCacheEntry struct {
+	key         string
+	data        []byte
+	contentType string
+}
+
+func newRenderCache(capacity int) *renderCache {
+	return &renderCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the cached bytes for key, if present, moving it to the front
+// of the eviction order.
+func (c *renderCache) Get(key string) (data []byte, contentType string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, found := c.items[key]
+	if !found {
+		return nil, "", false
+	}
+	c.ll.MoveToFront(el)
+	entry := el.Value.(*renderCacheEntry)
+	return entry.data, entry.contentType, true
+}
+
+// Put stores data under key, evicting least-recently-used entries before it
+// returns whenever the cache is over capacity.
+func (c *renderCache) Put(key string, data []byte, contentType string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, found := c.items[key]; found {
+		c.ll.MoveToFront(el)
+		entry := el.Value.(*renderCacheEntry)
+		entry.data = data
+		entry.contentType = contentType
+		return
+	}
+
+	el := c.ll.PushFront(&renderCacheEntry{key: key, data: data, contentType: contentType})
+	c.items[key] = el
+
+	for c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		if oldest == nil {
+			break
+		}
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*renderCacheEntry).key)
+	}
+}
+
+// barcodeRenderCache caches rendered label output across requests.
+var barcodeRenderCache = newRenderCache(64)
+
+// cacheKeyForRequest hashes every submitted form field plus format into a
+// stable key, so identical label requests (the common case when a template
+// is reprinted) hit the cache regardless of field order.
+func cacheKeyForRequest(r *http.Request, format string) string {
+	keys := make([]string, 0, len(r.Form))
+	for k := range r.Form {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("format=")
+	sb.WriteString(format)
+	for _, k := range keys {
+		sb.WriteByte('&')
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(strings.Join(r.Form[k], ","))
+	}
+
+	sum := sha256.Sum256([]byte(sb.String()))
+	return hex.EncodeToString(sum[:])
+}
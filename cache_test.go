@@ -0,0 +1,81 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestRenderCacheGetPut(t *testing.T) {
+	c := newRenderCache(2)
+
+	if _, _, ok := c.Get("missing"); ok {
+		t.Fatal("Get on empty cache returned ok=true")
+	}
+
+	c.Put("a", []byte("A"), "image/png")
+	data, contentType, ok := c.Get("a")
+	if !ok || string(data) != "A" || contentType != "image/png" {
+		t.Fatalf("Get(%q) = %q, %q, %v; want \"A\", \"image/png\", true", "a", data, contentType, ok)
+	}
+}
+
+func TestRenderCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newRenderCache(2)
+
+	c.Put("a", []byte("A"), "image/png")
+	c.Put("b", []byte("B"), "image/png")
+	// Touch "a" so "b" becomes the least-recently-used entry.
+	if _, _, ok := c.Get("a"); !ok {
+		t.Fatal("Get(\"a\") = ok=false, want true")
+	}
+
+	c.Put("c", []byte("C"), "image/png")
+
+	if _, _, ok := c.Get("b"); ok {
+		t.Fatal("Get(\"b\") = ok=true after eviction, want false")
+	}
+	if _, _, ok := c.Get("a"); !ok {
+		t.Fatal("Get(\"a\") = ok=false, want true (should have survived eviction)")
+	}
+	if _, _, ok := c.Get("c"); !ok {
+		t.Fatal("Get(\"c\") = ok=false, want true")
+	}
+}
+
+func TestRenderCachePutOverwritesExistingKey(t *testing.T) {
+	c := newRenderCache(2)
+
+	c.Put("a", []byte("old"), "image/png")
+	c.Put("a", []byte("new"), "image/svg+xml")
+
+	data, contentType, ok := c.Get("a")
+	if !ok || string(data) != "new" || contentType != "image/svg+xml" {
+		t.Fatalf("Get(\"a\") = %q, %q, %v; want \"new\", \"image/svg+xml\", true", data, contentType, ok)
+	}
+}
+
+func TestCacheKeyForRequestStableAcrossFieldOrder(t *testing.T) {
+	r1 := &http.Request{Form: url.Values{"b": {"2"}, "a": {"1"}}}
+	r2 := &http.Request{Form: url.Values{"a": {"1"}, "b": {"2"}}}
+
+	if cacheKeyForRequest(r1, "png") != cacheKeyForRequest(r2, "png") {
+		t.Fatal("cacheKeyForRequest produced different keys for the same fields in different order")
+	}
+}
+
+func TestCacheKeyForRequestDiffersByFormatAndValue(t *testing.T) {
+	base := &http.Request{Form: url.Values{"data1": {"123"}}}
+	changedValue := &http.Request{Form: url.Values{"data1": {"456"}}}
+
+	pngKey := cacheKeyForRequest(base, "png")
+	svgKey := cacheKeyForRequest(base, "svg")
+	changedKey := cacheKeyForRequest(changedValue, "png")
+
+	if pngKey == svgKey {
+		t.Fatal("cacheKeyForRequest produced the same key for different formats")
+	}
+	if pngKey == changedKey {
+		t.Fatal("cacheKeyForRequest produced the same key for different field values")
+	}
+}
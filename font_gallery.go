@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/fogleman/gg"
+)
+
+// fontPreviewText is the sample rendered for every font in the gallery,
+// chosen to exercise both ascenders/descenders and digits.
+const fontPreviewText = "The quick brown fox 0123"
+
+const defaultPreviewSize = 28
+
+// fontEntry describes one selectable font: either a registered BDF bitmap
+// font or a TTF file under static/.
+type fontEntry struct {
+	Name string // value submitted as FontChoice
+	Kind string // "bdf" or "ttf"
+}
+
+// registeredFonts lists every font selectable as a FontChoice: registered
+// BDF fonts plus any *.ttf file under static/, sorted by name.
+func registeredFonts() []fontEntry {
+	var entries []fontEntry
+
+	bdfFontsMu.RLock()
+	for name := range bdfFonts {
+		entries = append(entries, fontEntry{Name: name, Kind: "bdf"})
+	}
+	bdfFontsMu.RUnlock()
+
+	if matches, err := filepath.Glob(filepath.Join("static", "*.ttf")); err == nil {
+		for _, path := range matches {
+			entries = append(entries, fontEntry{Name: path, Kind: "ttf"})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// isRegisteredFont reports whether name is one of registeredFonts()'s
+// entries. FontChoice and /fonts?name= both go through this before ever
+// reaching gg.LoadFontFace, so a request can't point the server at an
+// arbitrary file on disk.
+func isRegisteredFont(name string) bool {
+	for _, f := range registeredFonts() {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fontsHandler serves the font gallery at /fonts and, given ?name=, a PNG
+// preview of that one font at /fonts?name=...&size=....
+func fontsHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		serveFontGallery(w)
+		return
+	}
+
+	size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+	if size <= 0 {
+		size = defaultPreviewSize
+	}
+
+	data, err := renderFontPreview(name, size)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(data)
+}
+
+// renderFontPreview draws fontPreviewText in the named font at size and
+// returns it as a PNG, using the BDF path when name is a registered bitmap
+// font and falling back to gg's TrueType loader otherwise.
+func renderFontPreview(name string, size int) ([]byte, error) {
+	padding := size / 2
+
+	if bdfFont, ok := lookupBDFFont(name); ok {
+		scale := 1
+		if bdfFont.BoundingBoxH > 0 {
+			scale = size / bdfFont.BoundingBoxH
+		}
+		if scale < 1 {
+			scale = 1
+		}
+
+		bounds := bdfFont.BoundString(fontPreviewText, scale)
+		dc := gg.NewContext(bounds.Dx()+padding*2, bounds.Dy()+padding*2)
+		dc.SetColor(color.White)
+		dc.Clear()
+		bdfFont.DrawString(dc.Image().(draw.Image), image.Pt(padding, padding), fontPreviewText, scale, color.Black)
+
+		return renderPNG(dc.Image())
+	}
+
+	if !isRegisteredFont(name) {
+		return nil, fmt.Errorf("unknown font %q", name)
+	}
+
+	dc := gg.NewContext(1, 1)
+	if err := dc.LoadFontFace(name, float64(size)); err != nil {
+		return nil, fmt.Errorf("failed to load font %q: %w", name, err)
+	}
+	textW, textH := dc.MeasureString(fontPreviewText)
+
+	dc = gg.NewContext(int(textW)+padding*2, int(textH)+padding*2)
+	dc.SetColor(color.White)
+	dc.Clear()
+	dc.LoadFontFace(name, float64(size))
+	dc.SetColor(color.Black)
+	dc.DrawStringAnchored(fontPreviewText, float64(padding), float64(padding), 0, 0.5)
+
+	return renderPNG(dc.Image())
+}
+
+// serveFontGallery renders a minimal index of every registered font, each
+// with a thumbnail pulled from /fonts?name=....
+func serveFontGallery(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, "<!doctype html><html><head><title>Fonts</title></head><body>\n")
+	for _, f := range registeredFonts() {
+		fmt.Fprintf(w, `<div><p>%s (%s)</p><img src="/fonts?name=%s"></div>`+"\n", f.Name, f.Kind, f.Name)
+	}
+	fmt.Fprint(w, "</body></html>")
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/DigitalBLItz-coder/barcode/bdf"
+	"github.com/fogleman/gg"
+)
+
+// bdfFonts holds every BDF font registered under static/*.bdf, keyed by
+// filename without extension (e.g. "5x8" for "static/5x8.bdf"), so a
+// FontChoice of "5x8" selects the bitmap path instead of a TrueType file.
+var (
+	bdfFontsMu sync.RWMutex
+	bdfFonts   = map[string]*bdf.Font{}
+)
+
+// loadBDFFonts scans dir for *.bdf files and registers each one. Bad fonts
+// are skipped rather than failing startup, since a single malformed font
+// shouldn't take down the whole server.
+func loadBDFFonts(dir string) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.bdf"))
+	if err != nil {
+		return
+	}
+
+	bdfFontsMu.Lock()
+	defer bdfFontsMu.Unlock()
+	for _, path := range matches {
+		font, err := bdf.Load(path)
+		if err != nil {
+			continue
+		}
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		bdfFonts[name] = font
+	}
+}
+
+// lookupBDFFont returns the registered BDF font for name, if any.
+func lookupBDFFont(name string) (*bdf.Font, bool) {
+	bdfFontsMu.RLock()
+	defer bdfFontsMu.RUnlock()
+	f, ok := bdfFonts[name]
+	return f, ok
+}
+
+// drawBDFStringAnchored draws s with font, centered at (cx, cy) the same way
+// gg.DrawStringAnchored(s, cx, cy, 0.5, 0.5) anchors TrueType text. The
+// integer scale is the largest one whose bitmap glyph height does not exceed
+// the requested text size (at least 1), pixel-doubled rather than resampled. scale and col
+// are passed straight through to DrawString rather than stored on font,
+// since font may be a shared registry entry drawn concurrently at different
+// sizes/colors.
+func drawBDFStringAnchored(dc *gg.Context, font *bdf.Font, s string, col color.Color, cx, cy float64, textSize int) {
+	scale := 1
+	if font.BoundingBoxH > 0 {
+		scale = textSize / font.BoundingBoxH
+	}
+	if scale < 1 {
+		scale = 1
+	}
+
+	bounds := font.BoundString(s, scale)
+	topLeft := image.Pt(
+		int(cx)-bounds.Dx()/2,
+		int(cy)-bounds.Dy()/2,
+	)
+
+	font.DrawString(dc.Image().(draw.Image), topLeft, s, scale, col)
+}
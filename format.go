@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/png"
+)
+
+// renderPNG rasterizes img as a PNG.
+func renderPNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("render png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderSVG converts img to a scalable, lossless SVG by run-length encoding
+// each row into <rect> elements, one per contiguous same-color span. This
+// turns any composed label - barcode bars, 2D modules, and text - into
+// vector output suitable for a print pipeline, without re-deriving geometry
+// per symbology.
+func renderSVG(img image.Image) []byte {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, w, h, w, h)
+
+	for y := 0; y < h; y++ {
+		runStart := 0
+		r0, g0, b0, a0 := img.At(bounds.Min.X, bounds.Min.Y+y).RGBA()
+		for x := 1; x <= w; x++ {
+			var r, g, b, a uint32
+			if x < w {
+				r, g, b, a = img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			}
+			if x == w || r != r0 || g != g0 || b != b0 || a != a0 {
+				if a0 != 0 {
+					fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="1" fill="#%02x%02x%02x" fill-opacity="%.3f"/>`,
+						runStart, y, x-runStart, r0>>8, g0>>8, b0>>8, float64(a0)/0xffff)
+				}
+				if x < w {
+					runStart = x
+					r0, g0, b0, a0 = r, g, b, a
+				}
+			}
+		}
+	}
+
+	buf.WriteString(`</svg>`)
+	return buf.Bytes()
+}
+
+// renderPDF wraps img as a single-page PDF, embedding it as a zlib-compressed
+// DeviceRGB image XObject under FlateDecode. It's a purpose-built minimal
+// writer rather than a general PDF library, since a label image is the only
+// content a print pipeline needs from this endpoint.
+func renderPDF(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	raw := make([]byte, 0, w*h*3)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			raw = append(raw, byte(r>>8), byte(g>>8), byte(b>>8))
+		}
+	}
+
+	var imgStream bytes.Buffer
+	zw := zlib.NewWriter(&imgStream)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, fmt.Errorf("render pdf: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("render pdf: %w", err)
+	}
+
+	content := []byte(fmt.Sprintf("q\n%d 0 0 %d 0 0 cm\n/Im0 Do\nQ", w, h))
+
+	var buf bytes.Buffer
+	offsets := make([]int, 6) // index 1..5 used
+
+	buf.WriteString("%PDF-1.4\n")
+
+	writeObj := func(n int, body string) {
+		offsets[n] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", n, body)
+	}
+
+	writeObj(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	writeObj(2, "<< /Type /Pages /Kids [3 0 R] /Count 1 >>")
+	writeObj(3, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /XObject << /Im0 4 0 R >> >> /Contents 5 0 R >>", w, h))
+
+	offsets[4] = buf.Len()
+	fmt.Fprintf(&buf, "4 0 obj\n<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /Length %d >>\nstream\n", w, h, imgStream.Len())
+	buf.Write(imgStream.Bytes())
+	buf.WriteString("\nendstream\nendobj\n")
+
+	offsets[5] = buf.Len()
+	fmt.Fprintf(&buf, "5 0 obj\n<< /Length %d >>\nstream\n%s\nendstream\nendobj\n", len(content), content)
+
+	xrefStart := buf.Len()
+	buf.WriteString("xref\n0 6\n")
+	buf.WriteString("0000000000 65535 f \n")
+	for n := 1; n <= 5; n++ {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offsets[n])
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size 6 /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF", xrefStart)
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"image"
+	"image/color"
+)
+
+// RotatedImage wraps an image.Image and presents it rotated clockwise by a
+// multiple of 90 degrees, remapping Bounds and At instead of resampling
+// pixels. Width and height swap for a 90 or 270 degree rotation.
+type RotatedImage struct {
+	Src     image.Image
+	Degrees int
+}
+
+// normalizedDegrees reduces Degrees to one of 0, 90, 180, 270.
+func (r RotatedImage) normalizedDegrees() int {
+	d := r.Degrees % 360
+	if d < 0 {
+		d += 360
+	}
+	return (d / 90) * 90
+}
+
+func (r RotatedImage) ColorModel() color.Model {
+	return r.Src.ColorModel()
+}
+
+func (r RotatedImage) Bounds() image.Rectangle {
+	b := r.Src.Bounds()
+	switch r.normalizedDegrees() {
+	case 90, 270:
+		return image.Rect(0, 0, b.Dy(), b.Dx())
+	default:
+		return image.Rect(0, 0, b.Dx(), b.Dy())
+	}
+}
+
+func (r RotatedImage) At(x, y int) color.Color {
+	b := r.Src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	switch r.normalizedDegrees() {
+	case 90:
+		return r.Src.At(b.Min.X+y, b.Min.Y+h-1-x)
+	case 180:
+		return r.Src.At(b.Min.X+w-1-x, b.Min.Y+h-1-y)
+	case 270:
+		return r.Src.At(b.Min.X+w-1-y, b.Min.Y+x)
+	default:
+		return r.Src.At(b.Min.X+x, b.Min.Y+y)
+	}
+}
+
+// LayoutOptions controls how composeBarcodes arranges cells beyond the
+// per-barcode fields already on BarcodeData.
+type LayoutOptions struct {
+	// Columns wraps barcodes into a grid after this many per row. 0 (or
+	// greater than len(barcodes)) keeps the original single-row layout.
+	Columns int
+
+	// FitWidth, if non-zero, scales the whole composed label proportionally
+	// so its width matches a fixed media width (e.g. 696px for 62mm tape).
+	FitWidth int
+}
+
+// resizeNearest scales src to w x h using nearest-neighbor sampling, the
+// same non-resampling approach used for BDF glyph scaling, so proportional
+// "fit to media width" scaling doesn't blur crisp barcode edges.
+func resizeNearest(src image.Image, w, h int) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	srcW, srcH := b.Dx(), b.Dy()
+	for y := 0; y < h; y++ {
+		sy := b.Min.Y + y*srcH/h
+		for x := 0; x < w; x++ {
+			sx := b.Min.X + x*srcW/w
+			dst.Set(x, y, src.At(sx, sy))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// solid2x1 is a 2-wide, 1-tall image with distinct pixels: A at (0,0), B at
+// (1,0). Rotating it lets each case check both the swapped Bounds and the
+// remapped pixel each degree produces.
+func solid2x1() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.RGBA{R: 1, A: 255}) // "A"
+	img.Set(1, 0, color.RGBA{R: 2, A: 255}) // "B"
+	return img
+}
+
+func TestRotatedImageBounds(t *testing.T) {
+	src := solid2x1()
+	cases := []struct {
+		degrees int
+		wantW   int
+		wantH   int
+	}{
+		{0, 2, 1},
+		{90, 1, 2},
+		{180, 2, 1},
+		{270, 1, 2},
+		{360, 2, 1}, // wraps to 0
+		{-90, 1, 2}, // wraps to 270
+	}
+	for _, c := range cases {
+		r := RotatedImage{Src: src, Degrees: c.degrees}
+		b := r.Bounds()
+		if b.Dx() != c.wantW || b.Dy() != c.wantH {
+			t.Errorf("degrees=%d: Bounds = %dx%d, want %dx%d", c.degrees, b.Dx(), b.Dy(), c.wantW, c.wantH)
+		}
+	}
+}
+
+func pixelR(c color.Color) uint32 {
+	r, _, _, _ := c.RGBA()
+	return r >> 8
+}
+
+func TestRotatedImageAt(t *testing.T) {
+	src := solid2x1() // A=(0,0), B=(1,0)
+
+	cases := []struct {
+		degrees int
+		x, y    int
+		want    uint32 // red channel: 1 = "A", 2 = "B"
+	}{
+		// Unrotated: left-to-right as source.
+		{0, 0, 0, 1},
+		{0, 1, 0, 2},
+		// 90 clockwise: a horizontal [A B] becomes a vertical column with A
+		// on top, B on bottom.
+		{90, 0, 0, 1},
+		{90, 0, 1, 2},
+		// 180: fully reversed.
+		{180, 0, 0, 2},
+		{180, 1, 0, 1},
+		// 270 clockwise (90 counter-clockwise): B on top, A on bottom.
+		{270, 0, 0, 2},
+		{270, 0, 1, 1},
+	}
+	for _, c := range cases {
+		r := RotatedImage{Src: src, Degrees: c.degrees}
+		got := pixelR(r.At(c.x, c.y))
+		if got != c.want {
+			t.Errorf("degrees=%d At(%d,%d) = %d, want %d", c.degrees, c.x, c.y, got, c.want)
+		}
+	}
+}
@@ -1,26 +1,53 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"html/template"
 	"image/color"
-	"image/png"
 	"net/http"
-	"os"
 	"strconv"
 
 	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/aztec"
 	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/code39"
+	"github.com/boombuler/barcode/datamatrix"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/pdf417"
+	"github.com/boombuler/barcode/qr"
 	"github.com/fogleman/gg"
 )
 
 const (
 	baseDPI = 96
+
+	// Symbology names accepted by the symbology1..symbology4 form fields.
+	symbologyCode128    = "code128"
+	symbologyQR         = "qr"
+	symbologyDataMatrix = "datamatrix"
+	symbologyAztec      = "aztec"
+	symbologyEAN        = "ean"
+	symbologyCode39     = "code39"
+	symbologyPDF417     = "pdf417"
 )
 
+// is2DSymbology reports whether a symbology renders as a square/rectangular
+// 2D code rather than a 1D bar pattern, so it should be scaled to a square
+// target size instead of stretched independently on each axis.
+func is2DSymbology(symbology string) bool {
+	switch symbology {
+	case symbologyQR, symbologyDataMatrix, symbologyAztec:
+		return true
+	default:
+		return false
+	}
+}
+
 // BarcodeData stores properties of each barcode
 type BarcodeData struct {
 	Data         string
+	Symbology    string
+	QRLevel      string // L, M, Q, or H; only used when Symbology == "qr"
 	Width        int
 	Height       int
 	PaddingColor string
@@ -28,6 +55,69 @@ type BarcodeData struct {
 	TextColor    string
 	TextSize     int
 	Bold         bool
+	Rotation     int // clockwise degrees: 0, 90, 180, or 270
+}
+
+// qrErrorCorrectionLevel maps the form's L/M/Q/H selection to the qr
+// package's error correction level, defaulting to M (the package default)
+// for unrecognized or empty input.
+func qrErrorCorrectionLevel(s string) qr.ErrorCorrectionLevel {
+	switch s {
+	case "L":
+		return qr.L
+	case "Q":
+		return qr.Q
+	case "H":
+		return qr.H
+	default:
+		return qr.M
+	}
+}
+
+// encodeBarcode validates b.Data for the requested symbology and returns the
+// unscaled barcode. It returns a descriptive error instead of a generic
+// failure so the HTTP handler can report what the caller did wrong.
+func encodeBarcode(b BarcodeData) (barcode.Barcode, error) {
+	switch b.Symbology {
+	case "", symbologyCode128:
+		return code128.Encode(b.Data)
+	case symbologyQR:
+		if len(b.Data) == 0 {
+			return nil, errors.New("qr: data must not be empty")
+		}
+		return qr.Encode(b.Data, qrErrorCorrectionLevel(b.QRLevel), qr.Auto)
+	case symbologyDataMatrix:
+		if len(b.Data) == 0 {
+			return nil, errors.New("datamatrix: data must not be empty")
+		}
+		return datamatrix.Encode(b.Data)
+	case symbologyAztec:
+		if len(b.Data) == 0 {
+			return nil, errors.New("aztec: data must not be empty")
+		}
+		return aztec.Encode([]byte(b.Data), 25, 0)
+	case symbologyEAN:
+		// ean.Encode accepts 7 or 12 digits (checksum auto-computed) and 8
+		// or 13 digits (checksum included).
+		switch len(b.Data) {
+		case 7, 8, 12, 13:
+		default:
+			return nil, fmt.Errorf("ean: data must be 7, 8, 12, or 13 digits, got %d", len(b.Data))
+		}
+		return ean.Encode(b.Data)
+	case symbologyCode39:
+		if len(b.Data) == 0 {
+			return nil, errors.New("code39: data must not be empty")
+		}
+		return code39.Encode(b.Data, false, true)
+	case symbologyPDF417:
+		if len(b.Data) == 0 {
+			return nil, errors.New("pdf417: data must not be empty")
+		}
+		return pdf417.Encode(b.Data, 5)
+	default:
+		return nil, fmt.Errorf("unknown symbology %q", b.Symbology)
+	}
 }
 
 // Parse HEX color to color.RGBA
@@ -39,10 +129,9 @@ func parseHexColor(s string) (color.Color, error) {
 	return color.RGBA{uint8(c >> 16), uint8(c >> 8 & 0xFF), uint8(c & 0xFF), 0xFF}, nil
 }
 
-// Handle barcode generation
-func generateBarcode(w http.ResponseWriter, r *http.Request) {
-	r.ParseForm()
-
+// parseBarcodesFromForm reads the up-to-4 barcode field groups (data1..data4,
+// width1..width4, etc.) out of a submitted form.
+func parseBarcodesFromForm(r *http.Request) []BarcodeData {
 	var barcodes []BarcodeData
 
 	for i := 1; i <= 4; i++ {
@@ -58,9 +147,14 @@ func generateBarcode(w http.ResponseWriter, r *http.Request) {
 		textColor := r.FormValue(fmt.Sprintf("text_color%d", i))
 		textSize, _ := strconv.Atoi(r.FormValue(fmt.Sprintf("text_size%d", i)))
 		bold := r.FormValue(fmt.Sprintf("bold%d", i)) == "on"
+		symbology := r.FormValue(fmt.Sprintf("symbology%d", i))
+		qrLevel := r.FormValue(fmt.Sprintf("qr_level%d", i))
+		rotation, _ := strconv.Atoi(r.FormValue(fmt.Sprintf("rotation%d", i)))
 
 		barcodes = append(barcodes, BarcodeData{
 			Data:         data,
+			Symbology:    symbology,
+			QRLevel:      qrLevel,
 			Width:        width,
 			Height:       height,
 			PaddingColor: paddingColor,
@@ -68,108 +162,272 @@ func generateBarcode(w http.ResponseWriter, r *http.Request) {
 			TextColor:    textColor,
 			TextSize:     textSize,
 			Bold:         bold,
+			Rotation:     rotation,
 		})
 	}
 
-	if len(barcodes) == 0 {
-		http.Error(w, "No barcode data provided", http.StatusBadRequest)
-		return
+	return barcodes
+}
+
+// parseLayoutOptions reads the grid/fit-to-width controls shared by the
+// /barcode and /print handlers out of a submitted form.
+func parseLayoutOptions(r *http.Request) LayoutOptions {
+	columns, _ := strconv.Atoi(r.FormValue("columns"))
+	fitWidth, _ := strconv.Atoi(r.FormValue("fit_width"))
+	return LayoutOptions{Columns: columns, FitWidth: fitWidth}
+}
+
+// renderCell draws a single barcode's padding background, barcode image, and
+// label text onto its own canvas, sized to exactly fit it. composeBarcodes
+// rotates and grid-places these cells; keeping each one self-contained is
+// what lets rotation be a wrapper around the finished cell image instead of
+// threading rotated coordinates through the drawing code.
+func renderCell(b BarcodeData) (*gg.Context, error) {
+	cellWidth := b.Width + b.TextSize*2   // Side padding based on text size
+	cellHeight := b.Height + b.TextSize*3 // Adaptive bottom padding based on text size
+
+	dc := gg.NewContext(cellWidth, cellHeight)
+
+	// Parse padding color
+	paddingColor, err := parseHexColor(b.PaddingColor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid padding color: %w", err)
 	}
 
-	totalWidth := 0
-	totalHeight := 0
+	// Create the barcode
+	bar, err := encodeBarcode(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate barcode: %w", err)
+	}
 
-	// Calculate total canvas size based on barcodes and padding
-	for _, barcode := range barcodes {
-		totalWidth += barcode.Width + (barcode.TextSize * 2) // Side padding based on text size
-		if barcode.Height+(barcode.TextSize*3) > totalHeight {
-			totalHeight = barcode.Height + (barcode.TextSize * 3) // Adaptive bottom padding based on text size
+	// Scale barcode to desired width and height (96 DPI). 2D symbologies
+	// are scaled to a square target so QR/DataMatrix/Aztec modules stay
+	// proportional instead of stretching horizontally only.
+	widthAtDPI := b.Width * baseDPI / 96
+	heightAtDPI := b.Height * baseDPI / 96
+	if is2DSymbology(b.Symbology) {
+		side := widthAtDPI
+		if heightAtDPI < side {
+			side = heightAtDPI
 		}
+		widthAtDPI, heightAtDPI = side, side
+	}
+	scaledBar, err := barcode.Scale(bar, widthAtDPI, heightAtDPI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scale barcode: %w", err)
 	}
 
-	dc := gg.NewContext(totalWidth, totalHeight)
-	xOffset := 0
+	// Draw background (padding color)
+	dc.SetColor(paddingColor)
+	dc.DrawRectangle(0, 0, float64(cellWidth), float64(cellHeight))
+	dc.Fill()
 
-	for _, b := range barcodes {
-		// Parse padding color
-		paddingColor, err := parseHexColor(b.PaddingColor)
-		if err != nil {
-			http.Error(w, "Invalid padding color", http.StatusBadRequest)
-			return
+	// Draw barcode image
+	dc.DrawImage(scaledBar, b.TextSize, b.TextSize)
+
+	// Parse text color
+	textColor, err := parseHexColor(b.TextColor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid text color: %w", err)
+	}
+	dc.SetColor(textColor)
+
+	// A non-empty FontChoice must name an entry in registeredFonts() - the
+	// same registry the /fonts gallery lists - so a request
+	// can't point LoadFontFace at an arbitrary file on disk.
+	if b.FontChoice != "" && !isRegisteredFont(b.FontChoice) {
+		return nil, fmt.Errorf("unknown font %q", b.FontChoice)
+	}
+
+	// Draw the text centered under the barcode. A FontChoice that names a
+	// registered BDF font draws crisp bitmap glyphs, pixel-doubled by an
+	// integer scale rather than resampled; otherwise
+	// fall back to the TrueType path (ignoring the now-unused arial_black
+	// split once a custom FontChoice is given).
+	textX := float64(b.TextSize + (scaledBar.Bounds().Dx() / 2))
+	textY := float64(b.TextSize + b.Height + b.TextSize)
+	if bdfFont, ok := lookupBDFFont(b.FontChoice); ok {
+		drawBDFStringAnchored(dc, bdfFont, b.Data, textColor, textX, textY, b.TextSize)
+	} else {
+		if b.FontChoice != "" {
+			dc.LoadFontFace(b.FontChoice, float64(b.TextSize))
+		} else if b.Bold {
+			dc.LoadFontFace("static/arial_black.ttf", float64(b.TextSize))
+		} else {
+			dc.LoadFontFace("static/arial.ttf", float64(b.TextSize))
 		}
+		dc.DrawStringAnchored(b.Data, textX, textY, 0.5, 0.5)
+	}
 
-		// Create the barcode
-		bar, err := code128.Encode(b.Data)
+	return dc, nil
+}
+
+// composeBarcodes renders each barcode into its own cell, rotates it per
+// BarcodeData.Rotation, and wraps cells into a grid of opts.Columns columns
+// (0 means a single row, the original layout). Column widths and row
+// heights are each the max of the cells they contain, so mixed rotations
+// and sizes still line up into a clean grid. If opts.FitWidth is set, the
+// finished grid is scaled proportionally to match it - e.g. 696px for 62mm
+// continuous tape - so the label fills a fixed-width media roll.
+func composeBarcodes(barcodes []BarcodeData, opts LayoutOptions) (*gg.Context, error) {
+	if len(barcodes) == 0 {
+		return nil, errors.New("no barcode data provided")
+	}
+
+	columns := opts.Columns
+	if columns <= 0 || columns > len(barcodes) {
+		columns = len(barcodes)
+	}
+	rows := (len(barcodes) + columns - 1) / columns
+
+	cells := make([]*RotatedImage, len(barcodes))
+	paddingColors := make([]color.Color, len(barcodes))
+	for i, b := range barcodes {
+		cell, err := renderCell(b)
 		if err != nil {
-			http.Error(w, "Failed to generate barcode", http.StatusInternalServerError)
-			return
+			return nil, err
 		}
+		cells[i] = &RotatedImage{Src: cell.Image(), Degrees: b.Rotation}
 
-		// Scale barcode to desired width and height (96 DPI)
-		widthAtDPI := b.Width * baseDPI / 96
-		heightAtDPI := b.Height * baseDPI / 96
-		scaledBar, err := barcode.Scale(bar, widthAtDPI, heightAtDPI)
+		paddingColor, err := parseHexColor(b.PaddingColor)
 		if err != nil {
-			http.Error(w, "Failed to scale barcode", http.StatusInternalServerError)
-			return
+			return nil, fmt.Errorf("invalid padding color: %w", err)
 		}
+		paddingColors[i] = paddingColor
+	}
 
-		// Draw background (padding color)
-		dc.SetColor(paddingColor)
-		dc.DrawRectangle(float64(xOffset), 0, float64(b.Width+(b.TextSize*2)), float64(totalHeight))
-		dc.Fill()
+	colWidths := make([]int, columns)
+	rowHeights := make([]int, rows)
+	for i, cell := range cells {
+		row, col := i/columns, i%columns
+		b := cell.Bounds()
+		if b.Dx() > colWidths[col] {
+			colWidths[col] = b.Dx()
+		}
+		if b.Dy() > rowHeights[row] {
+			rowHeights[row] = b.Dy()
+		}
+	}
 
-		// Draw barcode image
-		dc.DrawImage(scaledBar, xOffset+b.TextSize, b.TextSize)
+	totalWidth := 0
+	for _, w := range colWidths {
+		totalWidth += w
+	}
+	totalHeight := 0
+	for _, h := range rowHeights {
+		totalHeight += h
+	}
 
-		// Parse text color
-		textColor, err := parseHexColor(b.TextColor)
-		if err != nil {
-			http.Error(w, "Invalid text color", http.StatusBadRequest)
-			return
-		}
-		dc.SetColor(textColor)
+	dc := gg.NewContext(totalWidth, totalHeight)
+	yOffset := 0
+	for row := 0; row < rows; row++ {
+		xOffset := 0
+		for col := 0; col < columns; col++ {
+			i := row*columns + col
+			if i >= len(cells) {
+				break
+			}
 
-		// Set font
-		if b.Bold {
-			dc.LoadFontFace("static/arial_black.ttf", float64(b.TextSize))
-		} else {
-			dc.LoadFontFace("static/arial.ttf", float64(b.TextSize))
+			// Fill the cell's full grid slot with its padding color before
+			// placing it, so a cell smaller than the row/column max (mixed
+			// rotations or sizes in the same row/column) doesn't leave a
+			// transparent gap - which a thermal printer's threshold step
+			// would otherwise print as solid black.
+			slot := gg.NewContext(colWidths[col], rowHeights[row])
+			slot.SetColor(paddingColors[i])
+			slot.Clear()
+			slot.DrawImage(cells[i], 0, 0)
+
+			dc.DrawImage(slot.Image(), xOffset, yOffset)
+			xOffset += colWidths[col]
 		}
+		yOffset += rowHeights[row]
+	}
 
-		// Draw the text centered under the barcode
-		textX := float64(xOffset + b.TextSize + (b.Width / 2))
-		textY := float64(b.TextSize + b.Height + b.TextSize)
-		dc.DrawStringAnchored(b.Data, textX, textY, 0.5, 0.5)
+	if opts.FitWidth > 0 && totalWidth > 0 && opts.FitWidth != totalWidth {
+		scale := float64(opts.FitWidth) / float64(totalWidth)
+		fitHeight := int(float64(totalHeight) * scale)
+		return gg.NewContextForImage(resizeNearest(dc.Image(), opts.FitWidth, fitHeight)), nil
+	}
+
+	return dc, nil
+}
+
+// Handle barcode generation. Output is stateless: each request is rendered
+// (or served from the LRU render cache) and streamed straight back as the
+// response body, so two simultaneous requests never race over a shared file.
+func generateBarcode(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+
+	format := r.FormValue("format")
+	if format == "" {
+		format = "png"
+	}
+
+	contentType, ext, err := formatContentType(format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-		// Increment xOffset for the next barcode
-		xOffset += b.Width + (b.TextSize * 2)
+	key := cacheKeyForRequest(r, format)
+	if data, cachedType, ok := barcodeRenderCache.Get(key); ok {
+		writeRendered(w, data, cachedType, ext)
+		return
+	}
+
+	barcodes := parseBarcodesFromForm(r)
+	if len(barcodes) == 0 {
+		http.Error(w, "No barcode data provided", http.StatusBadRequest)
+		return
 	}
 
-	// Save the barcode image to a temporary path
-	filePath := "static/generated_barcode.png"
-	outFile, err := os.Create(filePath)
+	dc, err := composeBarcodes(barcodes, parseLayoutOptions(r))
 	if err != nil {
-		http.Error(w, "Failed to save barcode", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	png.Encode(outFile, dc.Image())
-	outFile.Close()
 
-	// Render the generated_barcode.html template
-	tmpl, err := template.ParseFiles("templates/generated_barcode.html")
+	var data []byte
+	switch format {
+	case "png":
+		data, err = renderPNG(dc.Image())
+	case "svg":
+		data = renderSVG(dc.Image())
+	case "pdf":
+		data, err = renderPDF(dc.Image())
+	}
 	if err != nil {
-		http.Error(w, "Error parsing template", http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to render %s: %v", format, err), http.StatusInternalServerError)
 		return
 	}
 
-	// Serve the page with the generated barcode path
-	data := struct {
-		BarcodePath string
-	}{
-		BarcodePath: filePath,
+	barcodeRenderCache.Put(key, data, contentType)
+	writeRendered(w, data, contentType, ext)
+}
+
+// formatContentType maps a ?format= value to its MIME type and file
+// extension, rejecting anything we don't know how to render.
+func formatContentType(format string) (contentType, ext string, err error) {
+	switch format {
+	case "png":
+		return "image/png", "png", nil
+	case "svg":
+		return "image/svg+xml", "svg", nil
+	case "pdf":
+		return "application/pdf", "pdf", nil
+	default:
+		return "", "", fmt.Errorf("unsupported format %q (want png, svg, or pdf)", format)
 	}
-	tmpl.Execute(w, data)
+}
+
+// writeRendered streams data back as the response body with a
+// Content-Disposition header naming it for print pipelines that save the
+// response straight to a file.
+func writeRendered(w http.ResponseWriter, data []byte, contentType, ext string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="barcode.%s"`, ext))
+	w.Write(data)
 }
 
 // Serve the form
@@ -178,9 +436,13 @@ func serveForm(w http.ResponseWriter, r *http.Request) {
 }
 
 func main() {
+	loadBDFFonts("static")
+
 	http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
 	http.HandleFunc("/", serveForm)
 	http.HandleFunc("/barcode", generateBarcode)
+	http.HandleFunc("/print", printLabel)
+	http.HandleFunc("/fonts", fontsHandler)
 
 	fmt.Println("Barcode Generator started Navigate to  http://localhost:8080 to generate")
 	err := http.ListenAndServe(":8080", nil)
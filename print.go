@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DigitalBLItz-coder/barcode/printer/ql"
+)
+
+// printDialTimeout bounds how long we wait to reach the printer before
+// giving up, so a misconfigured address doesn't hang the request.
+const printDialTimeout = 5 * time.Second
+
+// printerAllowlistEnv names the env var holding a comma-separated list of
+// host:port addresses printLabel is allowed to dial. Without this, a
+// client-supplied printer_addr would let printLabel dial anything reachable
+// from the server - an open TCP connector / SSRF primitive.
+const printerAllowlistEnv = "PRINTER_ALLOWLIST"
+
+// isAllowedPrinterAddr reports whether addr appears in printerAllowlistEnv.
+func isAllowedPrinterAddr(addr string) bool {
+	for _, allowed := range strings.Split(os.Getenv(printerAllowlistEnv), ",") {
+		if strings.TrimSpace(allowed) == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle direct-to-printer label output for Brother QL / PT-CBP thermal
+// printers, reusing the same barcode composition as /barcode.
+func printLabel(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+
+	barcodes := parseBarcodesFromForm(r)
+	if len(barcodes) == 0 {
+		http.Error(w, "No barcode data provided", http.StatusBadRequest)
+		return
+	}
+
+	dc, err := composeBarcodes(barcodes, parseLayoutOptions(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	printerAddr := r.FormValue("printer_addr")
+	if printerAddr == "" {
+		http.Error(w, "printer_addr is required (host:port)", http.StatusBadRequest)
+		return
+	}
+	if !isAllowedPrinterAddr(printerAddr) {
+		http.Error(w, fmt.Sprintf("printer_addr %q is not in the %s allowlist", printerAddr, printerAllowlistEnv), http.StatusForbidden)
+		return
+	}
+
+	mediaWidthMM, _ := strconv.Atoi(r.FormValue("media_width_mm"))
+	if mediaWidthMM == 0 {
+		mediaWidthMM = 62 // 62 mm continuous tape, the printer's common default
+	}
+	autoCut := r.FormValue("auto_cut") != "off"
+
+	job := ql.BuildJob(dc.Image(), ql.Media{WidthMM: byte(mediaWidthMM)}, autoCut)
+
+	conn, err := net.DialTimeout("tcp", printerAddr, printDialTimeout)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to reach printer: %v", err), http.StatusBadGateway)
+		return
+	}
+	defer conn.Close()
+
+	status, err := ql.Print(conn, job)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to print label: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	fmt.Fprintln(w, status.String())
+}
@@ -0,0 +1,171 @@
+// Package ql drives Brother QL-series (and PT-CBP) thermal label printers
+// over a raw TCP or USB connection using the printer's raster command
+// protocol: an ESC/P style init/status handshake followed by per-row raster
+// packets of the thresholded label image.
+package ql
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// RasterWidth is the fixed number of dots across the print head for 62 mm
+// continuous tape, the most common Brother QL media.
+const RasterWidth = 720
+
+// bytesPerLine is RasterWidth packed 8 dots to a byte.
+const bytesPerLine = RasterWidth / 8
+
+// rasterPacketLen is the payload length of each "g" raster-line command, as
+// documented by Brother's raster command reference.
+const rasterPacketLen = 90
+
+// Media describes the tape loaded in the printer by its WidthMM and
+// LengthMM. LengthMM is 0 for continuous tape.
+type Media struct {
+	WidthMM  byte
+	LengthMM byte
+}
+
+// Status reports the decoded 32-byte status reply the printer sends after
+// each raster job.
+type Status struct {
+	Printed   bool
+	CoverOpen bool
+	NoMedia   bool
+	MediaType byte
+	Raw       [32]byte
+}
+
+// String renders a human-readable summary suitable for an HTTP response.
+func (s Status) String() string {
+	switch {
+	case s.CoverOpen:
+		return "cover open"
+	case s.NoMedia:
+		return "no media"
+	case s.Printed:
+		return "printed"
+	default:
+		return fmt.Sprintf("unknown (error bitfield %02x %02x)", s.Raw[8], s.Raw[9])
+	}
+}
+
+// threshold converts img to 1-bpp, rows MSB-first, padded to RasterWidth and
+// centered horizontally. A pixel prints (bit=1) when its luminance is below
+// the midpoint; the QL raster format treats a set bit as "fire this dot".
+func threshold(img image.Image) [][]byte {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w > RasterWidth {
+		w = RasterWidth
+	}
+	xPad := (RasterWidth - w) / 2
+
+	rows := make([][]byte, h)
+	for y := 0; y < h; y++ {
+		row := make([]byte, bytesPerLine)
+		for x := 0; x < w; x++ {
+			c := color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray)
+			if c.Y < 128 {
+				bit := xPad + x
+				row[bit/8] |= 0x80 >> uint(bit%8)
+			}
+		}
+		rows[y] = row
+	}
+	return rows
+}
+
+// BuildJob composes the full raster command stream for img: invalidate,
+// initialize, media/quality info, auto-cut, the raster-line packets, and the
+// print-with-feed terminator.
+func BuildJob(img image.Image, media Media, autoCut bool) []byte {
+	var buf bytes.Buffer
+
+	// Invalidate: 200 null bytes clears any partial command the printer may
+	// have been mid-way through receiving.
+	buf.Write(make([]byte, 200))
+
+	// ESC @ : initialize.
+	buf.WriteString("\x1b@")
+
+	// ESC i a 01 : switch to raster mode.
+	buf.WriteString("\x1bia\x01")
+
+	rows := threshold(img)
+
+	// ESC i z : media/quality info. Byte layout per Brother's raster
+	// reference: flags, media type, width(mm), length(mm), raster number
+	// (4 bytes LE), starting page (0), reserved (0).
+	var mz bytes.Buffer
+	mz.WriteString("\x1biz")
+	mz.WriteByte(0x8e) // valid flags: media type + width + length + recovery
+	mz.WriteByte(0x0a) // media type: continuous length tape
+	mz.WriteByte(media.WidthMM)
+	mz.WriteByte(media.LengthMM)
+	rasterCount := uint32(len(rows))
+	mz.WriteByte(byte(rasterCount))
+	mz.WriteByte(byte(rasterCount >> 8))
+	mz.WriteByte(byte(rasterCount >> 16))
+	mz.WriteByte(byte(rasterCount >> 24))
+	mz.WriteByte(0x00) // starting page
+	mz.WriteByte(0x00) // reserved
+	buf.Write(mz.Bytes())
+
+	// ESC i M : auto-cut flag (bit 6 = 0x40 enables auto-cut after the job).
+	if autoCut {
+		buf.WriteString("\x1biM\x40")
+	} else {
+		buf.WriteString("\x1biM\x00")
+	}
+
+	// g <n> <data> : one raster-line packet per row, n == rasterPacketLen.
+	for _, row := range rows {
+		buf.WriteByte('g')
+		buf.WriteByte(0x00)
+		buf.WriteByte(byte(rasterPacketLen))
+		packet := make([]byte, rasterPacketLen)
+		copy(packet, row)
+		buf.Write(packet)
+	}
+
+	// Control-Z: print with feed.
+	buf.WriteByte(0x1a)
+
+	return buf.Bytes()
+}
+
+// Print writes job to conn and reads back the printer's 32-byte status
+// reply, decoding the error bitfields into a Status.
+func Print(conn io.ReadWriter, job []byte) (Status, error) {
+	if _, err := conn.Write(job); err != nil {
+		return Status{}, fmt.Errorf("ql: write job: %w", err)
+	}
+
+	var reply [32]byte
+	if _, err := io.ReadFull(conn, reply[:]); err != nil {
+		return Status{}, fmt.Errorf("ql: read status: %w", err)
+	}
+
+	return decodeStatus(reply), nil
+}
+
+// decodeStatus interprets the fixed 32-byte status reply. Offsets follow
+// Brother's raster command reference: error info 1/2 at 8-9, media type at
+// 11, and status type (phase change, etc.) at 18.
+func decodeStatus(reply [32]byte) Status {
+	s := Status{Raw: reply}
+	errInfo1 := reply[8]
+	errInfo2 := reply[9]
+	s.MediaType = reply[11]
+
+	s.CoverOpen = errInfo1&0x10 != 0
+	s.NoMedia = errInfo1&0x01 != 0 || errInfo2&0x01 != 0
+	s.Printed = !s.CoverOpen && !s.NoMedia && reply[18] == 0x02
+
+	return s
+}
@@ -0,0 +1,165 @@
+package ql
+
+import (
+	"image"
+	"testing"
+)
+
+// solidGray returns a w x h image filled with a single gray level.
+func solidGray(w, h int, y uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = y
+	}
+	return img
+}
+
+func TestThresholdPacksAndCentersRows(t *testing.T) {
+	// A 16-wide all-black image lands on a byte boundary (xPad is a multiple
+	// of 8), so it should set exactly its two packed bytes in the middle of
+	// the 90-byte-wide raster line and leave the rest untouched.
+	img := solidGray(16, 1, 0)
+	rows := threshold(img)
+	if len(rows) != 1 {
+		t.Fatalf("len(rows) = %d, want 1", len(rows))
+	}
+	if len(rows[0]) != bytesPerLine {
+		t.Fatalf("len(rows[0]) = %d, want %d", len(rows[0]), bytesPerLine)
+	}
+
+	xPad := (RasterWidth - 16) / 2
+	startByte := xPad / 8
+	for i, b := range rows[0] {
+		want := byte(0x00)
+		if i == startByte || i == startByte+1 {
+			want = 0xff
+		}
+		if b != want {
+			t.Errorf("rows[0][%d] = %02x, want %02x", i, b, want)
+		}
+	}
+}
+
+func TestThresholdWhiteRowIsAllZero(t *testing.T) {
+	img := solidGray(4, 2, 255)
+	rows := threshold(img)
+	for y, row := range rows {
+		for i, b := range row {
+			if b != 0x00 {
+				t.Errorf("rows[%d][%d] = %02x, want 00 for an all-white image", y, i, b)
+			}
+		}
+	}
+}
+
+func TestThresholdClampsWiderThanRasterWidth(t *testing.T) {
+	img := solidGray(RasterWidth+10, 1, 0)
+	rows := threshold(img)
+	if len(rows[0]) != bytesPerLine {
+		t.Fatalf("len(rows[0]) = %d, want %d", len(rows[0]), bytesPerLine)
+	}
+}
+
+func TestThresholdMidpointBoundary(t *testing.T) {
+	// Gray level 128 and above should not set its bit (only < 128 fires).
+	cases := []struct {
+		y        uint8
+		wantFire bool
+	}{
+		{127, true},
+		{128, false},
+	}
+	for _, c := range cases {
+		img := solidGray(1, 1, c.y)
+		rows := threshold(img)
+		xPad := (RasterWidth - 1) / 2
+		mask := byte(0x80 >> uint(xPad%8))
+		fired := rows[0][xPad/8]&mask != 0
+		if fired != c.wantFire {
+			t.Errorf("y=%d: fired = %v, want %v", c.y, fired, c.wantFire)
+		}
+	}
+}
+
+func TestDecodeStatus(t *testing.T) {
+	cases := []struct {
+		name      string
+		reply     [32]byte
+		wantPrint bool
+		wantCover bool
+		wantNoMed bool
+	}{
+		{
+			name:      "printed",
+			reply:     withReplyFields(0x00, 0x00, 0x02),
+			wantPrint: true,
+		},
+		{
+			name:      "cover open",
+			reply:     withReplyFields(0x10, 0x00, 0x00),
+			wantCover: true,
+		},
+		{
+			name:      "no media via errInfo1",
+			reply:     withReplyFields(0x01, 0x00, 0x00),
+			wantNoMed: true,
+		},
+		{
+			name:      "no media via errInfo2",
+			reply:     withReplyFields(0x00, 0x01, 0x00),
+			wantNoMed: true,
+		},
+		{
+			name:  "unknown status",
+			reply: withReplyFields(0x00, 0x00, 0x00),
+		},
+	}
+	for _, c := range cases {
+		got := decodeStatus(c.reply)
+		if got.Printed != c.wantPrint || got.CoverOpen != c.wantCover || got.NoMedia != c.wantNoMed {
+			t.Errorf("%s: decodeStatus = %+v, want Printed=%v CoverOpen=%v NoMedia=%v", c.name, got, c.wantPrint, c.wantCover, c.wantNoMed)
+		}
+	}
+}
+
+// withReplyFields builds a 32-byte status reply with the given error info
+// 1/2 bytes (offset 8/9) and status type byte (offset 18).
+func withReplyFields(errInfo1, errInfo2, statusType byte) [32]byte {
+	var reply [32]byte
+	reply[8] = errInfo1
+	reply[9] = errInfo2
+	reply[18] = statusType
+	return reply
+}
+
+func TestStatusString(t *testing.T) {
+	cases := []struct {
+		name   string
+		status Status
+		want   string
+	}{
+		{"cover open", Status{CoverOpen: true}, "cover open"},
+		{"no media", Status{NoMedia: true}, "no media"},
+		{"printed", Status{Printed: true}, "printed"},
+	}
+	for _, c := range cases {
+		if got := c.status.String(); got != c.want {
+			t.Errorf("%s: String() = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestBuildJobIncludesOneRasterPacketPerRow(t *testing.T) {
+	img := solidGray(8, 3, 0)
+	job := BuildJob(img, Media{WidthMM: 62}, true)
+
+	count := 0
+	for i := 0; i+2 < len(job); i++ {
+		if job[i] == 'g' && job[i+1] == 0x00 && job[i+2] == byte(rasterPacketLen) {
+			count++
+		}
+	}
+	if count != 3 {
+		t.Fatalf("raster packet count = %d, want 3 (one per row)", count)
+	}
+}